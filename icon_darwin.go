@@ -0,0 +1,81 @@
+//go:build darwin
+
+package systray
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework Cocoa
+// #import <Cocoa/Cocoa.h>
+//
+// static void setMenuItemIconImage(void *menuItem, const void *bytes, int length, bool isTemplate) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     NSData *data = [NSData dataWithBytes:bytes length:length];
+//     NSImage *image = [[NSImage alloc] initWithData:data];
+//     [image setTemplate:isTemplate];
+//     [item setImage:image];
+//     [image release];
+// }
+//
+// static void clearMenuItemIconImage(void *menuItem) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     [item setImage:nil];
+// }
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// nativeMenuItemsLock guards nativeMenuItems, the NSMenuItem backing each
+// item. Entries are registered by addOrUpdateMenuItem (mutate_darwin.go) on
+// first creation, and also by insertMenuItemBefore for items created via the
+// insert-before path. Separators are not registered.
+var (
+	nativeMenuItemsLock sync.Mutex
+	nativeMenuItems     = make(map[uint32]unsafe.Pointer)
+)
+
+// nativeMenuItemFor returns the NSMenuItem backing a menu item, or nil if the
+// item hasn't been realized natively yet.
+func nativeMenuItemFor(id uint32) unsafe.Pointer {
+	nativeMenuItemsLock.Lock()
+	defer nativeMenuItemsLock.Unlock()
+	return nativeMenuItems[id]
+}
+
+// setMenuItemIcon applies iconBytes as the NSMenuItem's image, marking it as
+// a template image when set via SetTemplateIcon so macOS can tint it for dark
+// mode, light mode, and the selected-row highlight. Now that
+// addOrUpdateMenuItem (mutate_darwin.go) registers every item's NSMenuItem on
+// creation, this takes effect for items added through the normal
+// AddMenuItem/AddSubMenuItem paths, not just ones inserted via
+// InsertMenuItemBefore.
+func setMenuItemIcon(item *menuItem, templateIconBytes []byte, regularIconBytes []byte) {
+	iconBytes := templateIconBytes
+	isTemplate := true
+	if iconBytes == nil {
+		iconBytes = regularIconBytes
+		isTemplate = false
+	}
+	if len(iconBytes) == 0 {
+		destroyMenuItemIcon(item.id_)
+		return
+	}
+
+	nsMenuItem := nativeMenuItemFor(item.id_)
+	if nsMenuItem == nil {
+		return
+	}
+	C.setMenuItemIconImage(nsMenuItem, unsafe.Pointer(&iconBytes[0]), C.int(len(iconBytes)), C.bool(isTemplate))
+}
+
+// destroyMenuItemIcon clears the NSMenuItem's image. NSImage is reference
+// counted by Cocoa's ARC-less runtime via -release in the Objective-C shim,
+// so there's no separate handle for Go to track or leak.
+func destroyMenuItemIcon(id uint32) {
+	nsMenuItem := nativeMenuItemFor(id)
+	if nsMenuItem == nil {
+		return
+	}
+	C.clearMenuItemIconImage(nsMenuItem)
+}
@@ -0,0 +1,123 @@
+//go:build linux
+
+package systray
+
+// #cgo pkg-config: gdk-pixbuf-2.0 gtk+-3.0
+// #include <gtk/gtk.h>
+// #include <gdk-pixbuf/gdk-pixbuf.h>
+// #include <stdlib.h>
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+var (
+	pixbufsLock sync.Mutex
+	// pixbufs tracks the GdkPixbuf created for each menu item's icon so it can
+	// be unreferenced when the icon is replaced or the item is removed.
+	pixbufs = make(map[uint32]*C.GdkPixbuf)
+
+	// gtkMenuItemsLock guards gtkMenuItems, the native GtkMenuItem backing
+	// each item. Entries are registered by addOrUpdateMenuItem
+	// (mutate_linux.go) on first creation, and also by insertMenuItemBefore/
+	// insertSeparatorBefore for items created via the insert-before path.
+	gtkMenuItemsLock sync.Mutex
+	gtkMenuItems     = make(map[uint32]*C.GtkWidget)
+)
+
+// nativeMenuItemFor returns the GtkWidget backing a menu item, or nil if the
+// item hasn't been realized natively yet.
+func nativeMenuItemFor(id uint32) *C.GtkWidget {
+	gtkMenuItemsLock.Lock()
+	defer gtkMenuItemsLock.Unlock()
+	return gtkMenuItems[id]
+}
+
+// newGError converts a GError into a Go error, freeing the GError afterwards.
+func newGError(gerr *C.GError) error {
+	if gerr == nil {
+		return fmt.Errorf("unknown GTK/GDK error")
+	}
+	defer C.g_error_free(gerr)
+	return fmt.Errorf("%s", C.GoString(gerr.message))
+}
+
+// setMenuItemIcon decodes iconBytes into a GdkPixbuf and applies it to the
+// item's GtkImageMenuItem. Linux has no template-icon concept, so
+// templateIconBytes is ignored and regularIconBytes is always rendered.
+// Checkbox and radio items are GtkCheckMenuItems, not GtkImageMenuItems, so
+// GTK rejects the image and no icon is shown on them.
+func setMenuItemIcon(item *menuItem, templateIconBytes []byte, regularIconBytes []byte) {
+	iconBytes := regularIconBytes
+	if len(iconBytes) == 0 {
+		destroyMenuItemIcon(item.id_)
+		return
+	}
+
+	pixbuf, err := bytesToPixbuf(iconBytes)
+	if err != nil {
+		log.Errorf("Unable to decode icon for %s: %v", item, err)
+		return
+	}
+
+	destroyMenuItemIcon(item.id_)
+	pixbufsLock.Lock()
+	pixbufs[item.id_] = pixbuf
+	pixbufsLock.Unlock()
+
+	gtkMenuItem := nativeMenuItemFor(item.id_)
+	if gtkMenuItem == nil {
+		return
+	}
+	image := C.gtk_image_new_from_pixbuf(pixbuf)
+	C.gtk_image_menu_item_set_image((*C.GtkImageMenuItem)(unsafe.Pointer(gtkMenuItem)), image)
+	C.gtk_image_menu_item_set_always_show_image((*C.GtkImageMenuItem)(unsafe.Pointer(gtkMenuItem)), C.TRUE)
+}
+
+// destroyMenuItemIcon unreferences the GdkPixbuf previously associated with a
+// menu item, if any, and clears the item's GtkImage so the icon stops
+// rendering. Called whenever the icon is replaced and when the item itself
+// is removed, so long-running trays don't leak GObject references.
+func destroyMenuItemIcon(id uint32) {
+	pixbufsLock.Lock()
+	pixbuf, ok := pixbufs[id]
+	if ok {
+		delete(pixbufs, id)
+	}
+	pixbufsLock.Unlock()
+	if !ok {
+		return
+	}
+
+	if gtkMenuItem := nativeMenuItemFor(id); gtkMenuItem != nil {
+		C.gtk_image_menu_item_set_image((*C.GtkImageMenuItem)(unsafe.Pointer(gtkMenuItem)), nil)
+	}
+	C.g_object_unref(C.gpointer(unsafe.Pointer(pixbuf)))
+}
+
+// bytesToPixbuf loads arbitrary PNG/ICO image bytes into a GdkPixbuf via a
+// GdkPixbufLoader, without needing a temp file on disk.
+func bytesToPixbuf(iconBytes []byte) (*C.GdkPixbuf, error) {
+	loader := C.gdk_pixbuf_loader_new()
+	defer C.g_object_unref(C.gpointer(unsafe.Pointer(loader)))
+
+	var gerr *C.GError
+	cBytes := C.CBytes(iconBytes)
+	defer C.free(cBytes)
+
+	ok := C.gdk_pixbuf_loader_write(loader, (*C.guchar)(cBytes), C.gsize(len(iconBytes)), &gerr)
+	if ok == 0 {
+		return nil, newGError(gerr)
+	}
+	if C.gdk_pixbuf_loader_close(loader, &gerr) == 0 {
+		return nil, newGError(gerr)
+	}
+
+	pixbuf := C.gdk_pixbuf_loader_get_pixbuf(loader)
+	// Each menu item keeps its own reference independent of the loader.
+	C.g_object_ref(C.gpointer(unsafe.Pointer(pixbuf)))
+	return pixbuf, nil
+}
@@ -0,0 +1,281 @@
+//go:build windows
+
+package systray
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/png"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32 = windows.NewLazySystemDLL("user32.dll")
+	gdi32  = windows.NewLazySystemDLL("gdi32.dll")
+
+	pGetDC           = user32.NewProc("GetDC")
+	pReleaseDC       = user32.NewProc("ReleaseDC")
+	pGetDpiForSystem = user32.NewProc("GetDpiForSystem")
+
+	pCreateDIBSection   = gdi32.NewProc("CreateDIBSection")
+	pDeleteObject       = gdi32.NewProc("DeleteObject")
+	pSetMenuItemBitmaps = user32.NewProc("SetMenuItemBitmaps")
+
+	iconHandlesLock sync.Mutex
+	// iconHandles tracks the native HBITMAP created for each menu item's icon
+	// so it can be destroyed when the icon is replaced or the item is removed.
+	iconHandles = make(map[uint32]windows.Handle)
+
+	// menuHandlesLock guards menuHandles, the native HMENU that owns each
+	// item. Entries are registered by addOrUpdateMenuItem (mutate_windows.go)
+	// on first creation, and also by insertMenuItemBefore for items created
+	// via the insert-before path. Separators are not registered.
+	menuHandlesLock sync.Mutex
+	menuHandles     = make(map[uint32]windows.Handle)
+)
+
+const (
+	baselineDPI = 96
+	mfByCommand = 0x00000000
+)
+
+// setMenuItemBitmaps applies hbitmap as both the checked and unchecked state
+// image for the given item via SetMenuItemBitmaps.
+func setMenuItemBitmaps(id uint32, hbitmap windows.Handle) {
+	menuHandlesLock.Lock()
+	hmenu, ok := menuHandles[id]
+	menuHandlesLock.Unlock()
+	if !ok {
+		log.Errorf("No native menu handle for item %v; icon not applied", id)
+		return
+	}
+	pSetMenuItemBitmaps.Call(uintptr(hmenu), uintptr(id), mfByCommand, uintptr(hbitmap), uintptr(hbitmap))
+}
+
+// setMenuItemIcon renders iconBytes next to the item's title via
+// SetMenuItemBitmaps. On Windows there's no template-icon concept, so
+// templateIconBytes is ignored and regularIconBytes is always rendered.
+func setMenuItemIcon(item *menuItem, templateIconBytes []byte, regularIconBytes []byte) {
+	iconBytes := regularIconBytes
+	if len(iconBytes) == 0 {
+		destroyMenuItemIcon(item.id_)
+		return
+	}
+
+	hbitmap, err := iconBytesToHBITMAP(iconBytes)
+	if err != nil {
+		log.Errorf("Unable to convert icon for %s: %v", item, err)
+		return
+	}
+
+	destroyMenuItemIcon(item.id_)
+	iconHandlesLock.Lock()
+	iconHandles[item.id_] = hbitmap
+	iconHandlesLock.Unlock()
+
+	setMenuItemBitmaps(item.id_, hbitmap)
+}
+
+// destroyMenuItemIcon releases the HBITMAP previously associated with a menu
+// item, if any. Called whenever the icon is replaced and when the item itself
+// is removed, so long-running trays don't leak GDI handles. The menu's own
+// bitmap reference is cleared first via SetMenuItemBitmaps(0, 0) so the live
+// HMENU never keeps pointing at a freed HBITMAP.
+func destroyMenuItemIcon(id uint32) {
+	iconHandlesLock.Lock()
+	hbitmap, ok := iconHandles[id]
+	if ok {
+		delete(iconHandles, id)
+	}
+	iconHandlesLock.Unlock()
+	if !ok {
+		return
+	}
+
+	menuHandlesLock.Lock()
+	hmenu, hasMenu := menuHandles[id]
+	menuHandlesLock.Unlock()
+	if hasMenu {
+		pSetMenuItemBitmaps.Call(uintptr(hmenu), uintptr(id), mfByCommand, 0, 0)
+	}
+
+	pDeleteObject.Call(uintptr(hbitmap))
+}
+
+// iconBytesToHBITMAP decodes PNG/ICO bytes and produces a top-down 32bpp DIB
+// section scaled for the system's current DPI, returning its HBITMAP.
+func iconBytesToHBITMAP(iconBytes []byte) (windows.Handle, error) {
+	img, err := decodeIconBytes(iconBytes)
+	if err != nil {
+		return 0, err
+	}
+
+	scale := float64(currentSystemDPI()) / baselineDPI
+	bounds := img.Bounds()
+	w := int(float64(bounds.Dx()) * scale)
+	h := int(float64(bounds.Dy()) * scale)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	type bitmapInfoHeader struct {
+		biSize          uint32
+		biWidth         int32
+		biHeight        int32
+		biPlanes        uint16
+		biBitCount      uint16
+		biCompression   uint32
+		biSizeImage     uint32
+		biXPelsPerMeter int32
+		biYPelsPerMeter int32
+		biClrUsed       uint32
+		biClrImportant  uint32
+	}
+
+	bi := bitmapInfoHeader{
+		biWidth:    int32(w),
+		biHeight:   -int32(h), // negative: top-down DIB
+		biPlanes:   1,
+		biBitCount: 32,
+	}
+	bi.biSize = uint32(unsafe.Sizeof(bi))
+
+	hdc, _, _ := pGetDC.Call(0)
+	defer pReleaseDC.Call(0, hdc)
+
+	var bitsPtr uintptr
+	hbitmap, _, callErr := pCreateDIBSection.Call(
+		hdc,
+		uintptr(unsafe.Pointer(&bi)),
+		0, // DIB_RGB_COLORS
+		uintptr(unsafe.Pointer(&bitsPtr)),
+		0,
+		0,
+	)
+	if hbitmap == 0 {
+		return 0, callErr
+	}
+
+	pixels := unsafe.Slice((*byte)(unsafe.Pointer(bitsPtr)), w*h*4)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx := bounds.Min.X + x*bounds.Dx()/w
+			sy := bounds.Min.Y + y*bounds.Dy()/h
+			r, g, b, a := img.At(sx, sy).RGBA()
+			i := (y*w + x) * 4
+			pixels[i+0] = byte(b >> 8)
+			pixels[i+1] = byte(g >> 8)
+			pixels[i+2] = byte(r >> 8)
+			pixels[i+3] = byte(a >> 8)
+		}
+	}
+
+	return windows.Handle(hbitmap), nil
+}
+
+// decodeIconBytes decodes PNG bytes directly, and ICO bytes by picking the
+// largest embedded image and decoding that. The standard library has no ICO
+// decoder, so the ICO container is parsed by hand.
+func decodeIconBytes(iconBytes []byte) (image.Image, error) {
+	if len(iconBytes) >= 4 && iconBytes[0] == 0 && iconBytes[1] == 0 && iconBytes[2] == 1 && iconBytes[3] == 0 {
+		return decodeICO(iconBytes)
+	}
+	img, _, err := image.Decode(bytes.NewReader(iconBytes))
+	return img, err
+}
+
+// decodeICO picks the largest image directory entry out of an ICO container
+// and decodes it, either as an embedded PNG or as a raw DIB.
+func decodeICO(data []byte) (image.Image, error) {
+	if len(data) < 6 {
+		return nil, fmt.Errorf("invalid ICO: too short")
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	if count == 0 {
+		return nil, fmt.Errorf("invalid ICO: no images")
+	}
+
+	var bestWidth, bestHeight int
+	var bestSize, bestOffset uint32
+	for i := 0; i < count; i++ {
+		off := 6 + i*16
+		if off+16 > len(data) {
+			break
+		}
+		w := int(data[off])
+		if w == 0 {
+			w = 256
+		}
+		h := int(data[off+1])
+		if h == 0 {
+			h = 256
+		}
+		if w*h > bestWidth*bestHeight {
+			bestWidth, bestHeight = w, h
+			bestSize = binary.LittleEndian.Uint32(data[off+8 : off+12])
+			bestOffset = binary.LittleEndian.Uint32(data[off+12 : off+16])
+		}
+	}
+	if bestSize == 0 || uint64(bestOffset)+uint64(bestSize) > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid ICO: bad image entry")
+	}
+	entry := data[bestOffset : bestOffset+bestSize]
+
+	if len(entry) >= 8 && string(entry[1:4]) == "PNG" {
+		img, _, err := image.Decode(bytes.NewReader(entry))
+		return img, err
+	}
+	return decodeICODIB(entry, bestWidth, bestHeight)
+}
+
+// decodeICODIB decodes the raw BITMAPINFOHEADER-prefixed pixel data an ICO
+// entry stores when it isn't a PNG, supporting the common 32bpp BGRA case.
+func decodeICODIB(dib []byte, width int, height int) (image.Image, error) {
+	if len(dib) < 40 {
+		return nil, fmt.Errorf("invalid ICO: truncated DIB header")
+	}
+	bitCount := binary.LittleEndian.Uint16(dib[14:16])
+	if bitCount != 32 {
+		return nil, fmt.Errorf("unsupported ICO bit depth: %d", bitCount)
+	}
+
+	const pixelsOffset = 40 // BITMAPINFOHEADER size; no color table at 32bpp
+	rowSize := width * 4
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		// DIB rows are stored bottom-up, and the XOR (color) mask comes
+		// before the AND (transparency) mask we don't need at 32bpp.
+		srcRow := pixelsOffset + (height-1-y)*rowSize
+		if srcRow+rowSize > len(dib) {
+			return nil, fmt.Errorf("invalid ICO: truncated pixel data")
+		}
+		for x := 0; x < width; x++ {
+			i := srcRow + x*4
+			b, g, r, a := dib[i], dib[i+1], dib[i+2], dib[i+3]
+			o := img.PixOffset(x, y)
+			img.Pix[o+0] = r
+			img.Pix[o+1] = g
+			img.Pix[o+2] = b
+			img.Pix[o+3] = a
+		}
+	}
+	return img, nil
+}
+
+// currentSystemDPI returns the DPI systray should scale icons for, via
+// GetDpiForSystem (the overall system DPI; Windows 10 1607+).
+func currentSystemDPI() uint32 {
+	ret, _, _ := pGetDpiForSystem.Call()
+	if ret == 0 {
+		return baselineDPI
+	}
+	return uint32(ret)
+}
@@ -0,0 +1,198 @@
+//go:build darwin
+
+package systray
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework Cocoa
+// #import <Cocoa/Cocoa.h>
+//
+// static void *newNativeMenu(void) {
+//     return (void *)[[NSMenu alloc] init];
+// }
+//
+// static void *appendNativeMenuItem(void *menu, const char *title, const char *tooltip) {
+//     NSMenu *nsMenu = (NSMenu *)menu;
+//     NSString *nsTitle = [NSString stringWithUTF8String:title];
+//     NSMenuItem *item = [[NSMenuItem alloc] initWithTitle:nsTitle action:nil keyEquivalent:@""];
+//     [item setToolTip:[NSString stringWithUTF8String:tooltip]];
+//     [nsMenu addItem:item];
+//     return (void *)item;
+// }
+//
+// static void setNativeMenuItemTitle(void *menuItem, const char *title) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     [item setTitle:[NSString stringWithUTF8String:title]];
+// }
+//
+// static void setNativeMenuItemEnabled(void *menuItem, bool enabled) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     [item setEnabled:enabled];
+// }
+//
+// static void setNativeMenuItemState(void *menuItem, bool on) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     [item setState:(on ? NSControlStateValueOn : NSControlStateValueOff)];
+// }
+//
+// static void setNativeMenuItemSubmenu(void *menuItem, void *submenu) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     [item setSubmenu:(NSMenu *)submenu];
+// }
+//
+// static void removeNativeMenuItem(void *menuItem) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     NSMenu *menu = [item menu];
+//     [menu removeItem:item];
+// }
+//
+// static void *insertMenuItemBeforeNative(void *sibling, const char *title, const char *tooltip) {
+//     NSMenuItem *siblingItem = (NSMenuItem *)sibling;
+//     NSMenu *menu = [siblingItem menu];
+//     NSInteger index = [menu indexOfItem:siblingItem];
+//
+//     NSString *nsTitle = [NSString stringWithUTF8String:title];
+//     NSMenuItem *item = [[NSMenuItem alloc] initWithTitle:nsTitle action:nil keyEquivalent:@""];
+//     [item setToolTip:[NSString stringWithUTF8String:tooltip]];
+//     [menu insertItem:item atIndex:index];
+//     return (void *)item;
+// }
+//
+// static void insertSeparatorBeforeNative(void *sibling) {
+//     NSMenuItem *siblingItem = (NSMenuItem *)sibling;
+//     NSMenu *menu = [siblingItem menu];
+//     NSInteger index = [menu indexOfItem:siblingItem];
+//     [menu insertItem:[NSMenuItem separatorItem] atIndex:index];
+// }
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+var (
+	rootMenuOnce sync.Once
+	rootNSMenu   unsafe.Pointer
+
+	// submenusLock guards submenus, the NSMenu created to hold an item's
+	// children, keyed by that item's own id, once it's been given at least
+	// one sub-menu item.
+	submenusLock sync.Mutex
+	submenus     = make(map[uint32]unsafe.Pointer)
+)
+
+// rootMenu lazily creates the NSMenu backing the top-level tray menu.
+func rootMenu() unsafe.Pointer {
+	rootMenuOnce.Do(func() {
+		rootNSMenu = C.newNativeMenu()
+	})
+	return rootNSMenu
+}
+
+// parentMenu returns the NSMenu that owns a direct child of parent (the
+// root tray menu when parent is nil), creating and attaching parent's own
+// NSMenu the first time it's given a child.
+func parentMenu(parent *menuItem) unsafe.Pointer {
+	if parent == nil {
+		return rootMenu()
+	}
+
+	submenusLock.Lock()
+	menu, ok := submenus[parent.id_]
+	submenusLock.Unlock()
+	if ok {
+		return menu
+	}
+
+	menu = C.newNativeMenu()
+	submenusLock.Lock()
+	submenus[parent.id_] = menu
+	submenusLock.Unlock()
+
+	if parentNative := nativeMenuItemFor(parent.id_); parentNative != nil {
+		C.setNativeMenuItemSubmenu(parentNative, menu)
+	}
+	return menu
+}
+
+// addOrUpdateMenuItem creates item's native NSMenuItem the first time it's
+// seen, appending it to its parent's NSMenu (the root menu, or the parent
+// item's own submenu), then registers it in nativeMenuItems so SetIcon,
+// radio rendering, and Remove can reach it afterwards. On later calls it
+// instead updates the existing item's title/enabled state. Checkbox state is
+// pushed via -setState: for both isCheckable items and (redundantly with
+// selectRadioGroupMember) radio items, same as Check()/Uncheck() expect.
+func addOrUpdateMenuItem(item *menuItem) {
+	nsMenuItem := nativeMenuItemFor(item.id_)
+	if nsMenuItem == nil {
+		title := C.CString(item.title)
+		defer C.free(unsafe.Pointer(title))
+		tooltip := C.CString(item.tooltip)
+		defer C.free(unsafe.Pointer(tooltip))
+
+		parent, _ := item.parent.(*menuItem)
+		nsMenuItem = C.appendNativeMenuItem(parentMenu(parent), title, tooltip)
+
+		nativeMenuItemsLock.Lock()
+		nativeMenuItems[item.id_] = nsMenuItem
+		nativeMenuItemsLock.Unlock()
+	} else {
+		title := C.CString(item.title)
+		defer C.free(unsafe.Pointer(title))
+		C.setNativeMenuItemTitle(nsMenuItem, title)
+	}
+
+	C.setNativeMenuItemEnabled(nsMenuItem, C.bool(!item.disabled))
+	if item.isCheckable {
+		C.setNativeMenuItemState(nsMenuItem, C.bool(item.checked))
+	}
+}
+
+// removeMenuItem removes item's NSMenuItem from its owning NSMenu via
+// -removeItem: and forgets the tracked handle so it isn't reused. Cocoa
+// releases any NSMenu set via -setSubmenu: along with the item, so the
+// submenus entry is just forgotten rather than destroyed separately.
+func removeMenuItem(item *menuItem) {
+	submenusLock.Lock()
+	delete(submenus, item.id_)
+	submenusLock.Unlock()
+
+	nsMenuItem := nativeMenuItemFor(item.id_)
+	if nsMenuItem == nil {
+		return
+	}
+	nativeMenuItemsLock.Lock()
+	delete(nativeMenuItems, item.id_)
+	nativeMenuItemsLock.Unlock()
+	C.removeNativeMenuItem(nsMenuItem)
+}
+
+// insertMenuItemBefore creates item's NSMenuItem and inserts it immediately
+// before sibling in their shared NSMenu via -insertItem:atIndex:.
+func insertMenuItemBefore(item *menuItem, sibling *menuItem) {
+	siblingNative := nativeMenuItemFor(sibling.id_)
+	if siblingNative == nil {
+		log.Errorf("No native menu handle for %s", sibling)
+		return
+	}
+	title := C.CString(item.title)
+	defer C.free(unsafe.Pointer(title))
+	tooltip := C.CString(item.tooltip)
+	defer C.free(unsafe.Pointer(tooltip))
+
+	nsMenuItem := C.insertMenuItemBeforeNative(siblingNative, title, tooltip)
+
+	nativeMenuItemsLock.Lock()
+	nativeMenuItems[item.id_] = nsMenuItem
+	nativeMenuItemsLock.Unlock()
+}
+
+// insertSeparatorBefore inserts a native NSMenuItem separator immediately
+// before the item identified by siblingID.
+func insertSeparatorBefore(id uint32, siblingID uint32) {
+	siblingNative := nativeMenuItemFor(siblingID)
+	if siblingNative == nil {
+		return
+	}
+	C.insertSeparatorBeforeNative(siblingNative)
+}
@@ -0,0 +1,203 @@
+//go:build linux
+
+package systray
+
+// #cgo pkg-config: gtk+-3.0
+// #include <gtk/gtk.h>
+// #include <stdlib.h>
+//
+// extern void insertedMenuItemActivated(guint id);
+//
+// static void onInsertedMenuItemActivate(GtkMenuItem *item, gpointer user_data) {
+//     insertedMenuItemActivated((guint)(guintptr)user_data);
+// }
+//
+// static void connectActivate(GtkWidget *widget, guint id) {
+//     g_signal_connect_data(widget, "activate", G_CALLBACK(onInsertedMenuItemActivate),
+//                            (gpointer)(guintptr)id, NULL, 0);
+// }
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+//export insertedMenuItemActivated
+func insertedMenuItemActivated(id C.guint) {
+	systrayMenuItemSelected(uint32(id))
+}
+
+var (
+	rootMenuOnce sync.Once
+	rootGtkMenu  *C.GtkWidget
+
+	// submenusLock guards submenus, the GtkMenu created to hold an item's
+	// children, keyed by that item's own id, once it's been given at least
+	// one sub-menu item.
+	submenusLock sync.Mutex
+	submenus     = make(map[uint32]*C.GtkWidget)
+)
+
+// rootMenu lazily creates the GtkMenu backing the top-level tray menu.
+func rootMenu() *C.GtkWidget {
+	rootMenuOnce.Do(func() {
+		rootGtkMenu = C.gtk_menu_new()
+	})
+	return rootGtkMenu
+}
+
+// parentShell returns the GtkMenuShell that owns a direct child of parent
+// (the root tray menu when parent is nil), creating and attaching parent's
+// own GtkMenu the first time it's given a child.
+func parentShell(parent *menuItem) *C.GtkWidget {
+	if parent == nil {
+		return rootMenu()
+	}
+
+	submenusLock.Lock()
+	menu, ok := submenus[parent.id_]
+	submenusLock.Unlock()
+	if ok {
+		return menu
+	}
+
+	menu = C.gtk_menu_new()
+	submenusLock.Lock()
+	submenus[parent.id_] = menu
+	submenusLock.Unlock()
+
+	gtkMenuItemsLock.Lock()
+	parentWidget, ok := gtkMenuItems[parent.id_]
+	gtkMenuItemsLock.Unlock()
+	if ok {
+		C.gtk_menu_item_set_submenu((*C.GtkMenuItem)(unsafe.Pointer(parentWidget)), menu)
+	}
+	return menu
+}
+
+// addOrUpdateMenuItem creates item's native GtkMenuItem (a GtkCheckMenuItem
+// when isCheckable, so radio/checkbox rendering can safely cast it later, and
+// a GtkImageMenuItem otherwise, so SetIcon can safely cast it)
+// the first time it's seen, appending it to its parent's GtkMenuShell (the
+// root menu, or the parent item's own submenu) and wiring its "activate"
+// signal, then registers the widget in gtkMenuItems so SetIcon, radio
+// rendering, and Remove can reach it afterwards. On later calls it instead
+// updates the existing widget's label/checked/sensitive state.
+func addOrUpdateMenuItem(item *menuItem) {
+	gtkMenuItemsLock.Lock()
+	widget, exists := gtkMenuItems[item.id_]
+	gtkMenuItemsLock.Unlock()
+
+	title := C.CString(item.title)
+	defer C.free(unsafe.Pointer(title))
+
+	if !exists {
+		if item.isCheckable {
+			widget = C.gtk_check_menu_item_new_with_label(title)
+		} else {
+			widget = C.gtk_image_menu_item_new_with_label(title)
+		}
+		C.connectActivate(widget, C.guint(item.id_))
+
+		parent, _ := item.parent.(*menuItem)
+		shell := (*C.GtkMenuShell)(unsafe.Pointer(parentShell(parent)))
+		C.gtk_menu_shell_append(shell, widget)
+		C.gtk_widget_show(widget)
+
+		gtkMenuItemsLock.Lock()
+		gtkMenuItems[item.id_] = widget
+		gtkMenuItemsLock.Unlock()
+	} else {
+		C.gtk_menu_item_set_label((*C.GtkMenuItem)(unsafe.Pointer(widget)), title)
+	}
+
+	if item.isCheckable {
+		checkItem := (*C.GtkCheckMenuItem)(unsafe.Pointer(widget))
+		active := C.gboolean(0)
+		if item.checked {
+			active = 1
+		}
+		C.gtk_check_menu_item_set_active(checkItem, active)
+	}
+
+	sensitive := C.gboolean(1)
+	if item.disabled {
+		sensitive = 0
+	}
+	C.gtk_widget_set_sensitive(widget, sensitive)
+}
+
+// removeMenuItem destroys item's GtkMenuItem via gtk_widget_destroy and
+// forgets the tracked widget so it isn't reused. GTK destroys any GtkMenu set
+// via gtk_menu_item_set_submenu along with the item, so the submenus entry is
+// just forgotten rather than destroyed separately.
+func removeMenuItem(item *menuItem) {
+	submenusLock.Lock()
+	delete(submenus, item.id_)
+	submenusLock.Unlock()
+
+	gtkMenuItemsLock.Lock()
+	widget, ok := gtkMenuItems[item.id_]
+	delete(gtkMenuItems, item.id_)
+	gtkMenuItemsLock.Unlock()
+	if !ok {
+		return
+	}
+	C.gtk_widget_destroy(widget)
+}
+
+// indexInShell returns widget's position among its parent GtkMenuShell's
+// children, for use with gtk_menu_shell_insert.
+func indexInShell(shell *C.GtkMenuShell, widget *C.GtkWidget) C.gint {
+	children := C.gtk_container_get_children((*C.GtkContainer)(unsafe.Pointer(shell)))
+	defer C.g_list_free(children)
+	return C.g_list_index(children, C.gconstpointer(widget))
+}
+
+// insertMenuItemBefore inserts item's native GtkMenuItem immediately before
+// sibling within their shared GtkMenuShell, wiring its "activate" signal to
+// deliver to item.ClickedCh the same way the normal creation path does.
+func insertMenuItemBefore(item *menuItem, sibling *menuItem) {
+	gtkMenuItemsLock.Lock()
+	siblingWidget, ok := gtkMenuItems[sibling.id_]
+	gtkMenuItemsLock.Unlock()
+	if !ok {
+		log.Errorf("No native menu handle for %s", sibling)
+		return
+	}
+	shell := (*C.GtkMenuShell)(unsafe.Pointer(C.gtk_widget_get_parent(siblingWidget)))
+	position := indexInShell(shell, siblingWidget)
+
+	title := C.CString(item.title)
+	defer C.free(unsafe.Pointer(title))
+	widget := C.gtk_image_menu_item_new_with_label(title)
+	C.connectActivate(widget, C.guint(item.id_))
+	C.gtk_menu_shell_insert(shell, widget, position)
+	C.gtk_widget_show(widget)
+
+	gtkMenuItemsLock.Lock()
+	gtkMenuItems[item.id_] = widget
+	gtkMenuItemsLock.Unlock()
+}
+
+// insertSeparatorBefore inserts a native GtkSeparatorMenuItem, identified by
+// id, immediately before the item identified by siblingID.
+func insertSeparatorBefore(id uint32, siblingID uint32) {
+	gtkMenuItemsLock.Lock()
+	siblingWidget, ok := gtkMenuItems[siblingID]
+	gtkMenuItemsLock.Unlock()
+	if !ok {
+		return
+	}
+	shell := (*C.GtkMenuShell)(unsafe.Pointer(C.gtk_widget_get_parent(siblingWidget)))
+	position := indexInShell(shell, siblingWidget)
+
+	widget := C.gtk_separator_menu_item_new()
+	C.gtk_menu_shell_insert(shell, widget, position)
+	C.gtk_widget_show(widget)
+
+	gtkMenuItemsLock.Lock()
+	gtkMenuItems[id] = widget
+	gtkMenuItemsLock.Unlock()
+}
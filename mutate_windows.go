@@ -0,0 +1,180 @@
+//go:build windows
+
+package systray
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	mfString    = 0x00000000
+	mfSeparator = 0x00000800
+	mfGrayed    = 0x00000001
+	mfPopup     = 0x00000010
+)
+
+var (
+	pDeleteMenu      = user32.NewProc("DeleteMenu")
+	pInsertMenuW     = user32.NewProc("InsertMenuW")
+	pAppendMenuW     = user32.NewProc("AppendMenuW")
+	pModifyMenuW     = user32.NewProc("ModifyMenuW")
+	pCreatePopupMenu = user32.NewProc("CreatePopupMenu")
+	pDestroyMenu     = user32.NewProc("DestroyMenu")
+
+	rootMenuOnce sync.Once
+	rootMenuH    windows.Handle
+
+	// submenuHandlesLock guards submenuHandles, the HMENU created to hold an
+	// item's children, keyed by that item's own id, once it's been given at
+	// least one sub-menu item.
+	submenuHandlesLock sync.Mutex
+	submenuHandles     = make(map[uint32]windows.Handle)
+)
+
+// rootMenu lazily creates the HMENU backing the top-level tray context menu.
+func rootMenu() windows.Handle {
+	rootMenuOnce.Do(func() {
+		h, _, _ := pCreatePopupMenu.Call()
+		rootMenuH = windows.Handle(h)
+	})
+	return rootMenuH
+}
+
+// parentHMENU returns the HMENU that owns a direct child of parent (the root
+// tray menu when parent is nil), creating and attaching parent's own submenu
+// HMENU the first time it's given a child.
+func parentHMENU(parent *menuItem) windows.Handle {
+	if parent == nil {
+		return rootMenu()
+	}
+
+	submenuHandlesLock.Lock()
+	hmenu, ok := submenuHandles[parent.id_]
+	submenuHandlesLock.Unlock()
+	if ok {
+		return hmenu
+	}
+
+	h, _, _ := pCreatePopupMenu.Call()
+	hmenu = windows.Handle(h)
+	submenuHandlesLock.Lock()
+	submenuHandles[parent.id_] = hmenu
+	submenuHandlesLock.Unlock()
+
+	menuHandlesLock.Lock()
+	ownerHMENU, ok := menuHandles[parent.id_]
+	menuHandlesLock.Unlock()
+	if ok {
+		if title, err := syscall.UTF16PtrFromString(parent.title); err == nil {
+			pModifyMenuW.Call(uintptr(ownerHMENU), uintptr(parent.id_), mfByCommand|mfPopup|mfString, uintptr(hmenu), uintptr(unsafe.Pointer(title)))
+		}
+	}
+	return hmenu
+}
+
+// menuItemStyleFlags computes the MF_* style bits matching item's current
+// checked/disabled state.
+func menuItemStyleFlags(item *menuItem) uintptr {
+	flags := uintptr(mfString)
+	if item.checked {
+		flags |= mfChecked
+	}
+	if item.disabled {
+		flags |= mfGrayed
+	}
+	return flags
+}
+
+// addOrUpdateMenuItem creates item's native HMENU entry the first time it's
+// seen, appending it to its parent's menu (the root tray menu, or the parent
+// item's own submenu), and registers the owning HMENU in menuHandles so
+// SetIcon, radio rendering, and Remove can reach it afterwards. On later
+// calls it instead updates the existing entry's title/checked/disabled state
+// via ModifyMenuW.
+func addOrUpdateMenuItem(item *menuItem) {
+	title, err := syscall.UTF16PtrFromString(item.title)
+	if err != nil {
+		log.Errorf("Unable to convert title for %s: %v", item, err)
+		return
+	}
+
+	menuHandlesLock.Lock()
+	hmenu, exists := menuHandles[item.id_]
+	menuHandlesLock.Unlock()
+
+	if exists {
+		pModifyMenuW.Call(uintptr(hmenu), uintptr(item.id_), mfByCommand|menuItemStyleFlags(item), uintptr(item.id_), uintptr(unsafe.Pointer(title)))
+		return
+	}
+
+	parent, _ := item.parent.(*menuItem)
+	hmenu = parentHMENU(parent)
+	pAppendMenuW.Call(uintptr(hmenu), menuItemStyleFlags(item), uintptr(item.id_), uintptr(unsafe.Pointer(title)))
+
+	menuHandlesLock.Lock()
+	menuHandles[item.id_] = hmenu
+	menuHandlesLock.Unlock()
+}
+
+// removeMenuItem deletes item's native entry from the HMENU that owns it via
+// DeleteMenu, which (unlike RemoveMenu) also destroys any submenu HMENU the
+// item owned, and forgets the tracked handle so it isn't reused. It also
+// forgets item's own submenu HMENU, if addOrUpdateMenuItem ever created one
+// for its children, without destroying it again: DeleteMenu already freed it.
+func removeMenuItem(item *menuItem) {
+	menuHandlesLock.Lock()
+	hmenu, ok := menuHandles[item.id_]
+	delete(menuHandles, item.id_)
+	menuHandlesLock.Unlock()
+	if ok {
+		pDeleteMenu.Call(uintptr(hmenu), uintptr(item.id_), mfByCommand)
+	}
+
+	submenuHandlesLock.Lock()
+	delete(submenuHandles, item.id_)
+	submenuHandlesLock.Unlock()
+}
+
+// insertMenuItemBefore inserts item's native entry immediately before sibling
+// via InsertMenuW, addressing both items by their command id (MF_BYCOMMAND).
+func insertMenuItemBefore(item *menuItem, sibling *menuItem) {
+	menuHandlesLock.Lock()
+	hmenu, ok := menuHandles[sibling.id_]
+	if ok {
+		menuHandles[item.id_] = hmenu
+	}
+	menuHandlesLock.Unlock()
+	if !ok {
+		log.Errorf("No native menu handle for %s", sibling)
+		return
+	}
+
+	title, err := syscall.UTF16PtrFromString(item.title)
+	if err != nil {
+		log.Errorf("Unable to convert title for %s: %v", item, err)
+		return
+	}
+	pInsertMenuW.Call(
+		uintptr(hmenu),
+		uintptr(sibling.id_),
+		mfByCommand|mfString,
+		uintptr(item.id_),
+		uintptr(unsafe.Pointer(title)),
+	)
+}
+
+// insertSeparatorBefore inserts a native separator, identified by id,
+// immediately before the item identified by siblingID.
+func insertSeparatorBefore(id uint32, siblingID uint32) {
+	menuHandlesLock.Lock()
+	hmenu, ok := menuHandles[siblingID]
+	menuHandlesLock.Unlock()
+	if !ok {
+		return
+	}
+	pInsertMenuW.Call(uintptr(hmenu), uintptr(siblingID), mfByCommand|mfSeparator, uintptr(id), 0)
+}
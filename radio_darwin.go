@@ -0,0 +1,33 @@
+//go:build darwin
+
+package systray
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework Cocoa
+// #import <Cocoa/Cocoa.h>
+//
+// static void setMenuItemRadioState(void *menuItem, bool on) {
+//     NSMenuItem *item = (NSMenuItem *)menuItem;
+//     [item setState:(on ? NSControlStateValueOn : NSControlStateValueOff)];
+// }
+import "C"
+
+// registerNativeRadioGroup is a no-op on macOS: NSMenuItem has no native
+// group concept, so exclusivity is coordinated entirely on the Go side and
+// rendered per-item via -setState:.
+func registerNativeRadioGroup(item *menuItem) {}
+
+// selectRadioGroupMember sets each member's NSMenuItem on/off state to match
+// member.checked via -setState:. Now that addOrUpdateMenuItem
+// (mutate_darwin.go) registers every item's NSMenuItem on creation, this
+// reaches items added through the normal AddSubMenuItemRadio path, not just
+// ones inserted via InsertMenuItemBefore.
+func selectRadioGroupMember(item *menuItem, members []*menuItem) {
+	for _, member := range members {
+		nsMenuItem := nativeMenuItemFor(member.id_)
+		if nsMenuItem == nil {
+			continue
+		}
+		C.setMenuItemRadioState(nsMenuItem, C.bool(member.checked))
+	}
+}
@@ -0,0 +1,76 @@
+//go:build windows
+
+package systray
+
+import "unsafe"
+
+const (
+	miimFtype     = 0x00000100
+	mftString     = 0x00000000
+	mftRadioCheck = 0x00000200
+	mfChecked     = 0x00000008
+	mfUnchecked   = 0x00000000
+)
+
+var pSetMenuItemInfoW = user32.NewProc("SetMenuItemInfoW")
+var pCheckMenuItem = user32.NewProc("CheckMenuItem")
+
+// menuItemInfoW mirrors the fields of Win32's MENUITEMINFOW that are needed
+// to switch an item's render style to a radio dot.
+type menuItemInfoW struct {
+	cbSize        uint32
+	fMask         uint32
+	fType         uint32
+	fState        uint32
+	wID           uint32
+	hSubMenu      uintptr
+	hbmpChecked   uintptr
+	hbmpUnchecked uintptr
+	dwItemData    uintptr
+	cch           uint32
+	dwTypeData    uintptr
+	hbmpItem      uintptr
+}
+
+// registerNativeRadioGroup marks item's native menu entry with the
+// MFT_RADIOCHECK style bit so Windows renders a radio dot instead of a
+// checkmark when it's checked.
+func registerNativeRadioGroup(item *menuItem) {
+	menuHandlesLock.Lock()
+	hmenu, ok := menuHandles[item.id_]
+	menuHandlesLock.Unlock()
+	if !ok {
+		return
+	}
+	info := menuItemInfoW{fMask: miimFtype, fType: mftRadioCheck | mftString}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+	pSetMenuItemInfoW.Call(uintptr(hmenu), uintptr(item.id_), 0, uintptr(unsafe.Pointer(&info)))
+}
+
+// selectRadioGroupMember applies the exclusive selection to every member of
+// item's radio group via CheckMenuItem. Members of a Go-side group aren't
+// guaranteed contiguous command ids, so CheckMenuRadioItem's idFirst/idLast
+// range can't be used directly.
+//
+// Deviation from request: the original ask was specifically for
+// CheckMenuRadioItem. Item ids are allocated from a single global counter
+// shared across all groups and parents, so a group's members can't be
+// guaranteed to land on a contiguous id range the way CheckMenuRadioItem
+// requires; per-member CheckMenuItem calls with the MFT_RADIOCHECK style bit
+// (see registerNativeRadioGroup) render identically. Flagging for requester
+// confirmation rather than merging this substitution silently.
+func selectRadioGroupMember(item *menuItem, members []*menuItem) {
+	for _, member := range members {
+		menuHandlesLock.Lock()
+		hmenu, ok := menuHandles[member.id_]
+		menuHandlesLock.Unlock()
+		if !ok {
+			continue
+		}
+		state := uintptr(mfUnchecked)
+		if member.checked {
+			state = mfChecked
+		}
+		pCheckMenuItem.Call(uintptr(hmenu), uintptr(member.id_), uintptr(mfByCommand)|state)
+	}
+}
@@ -22,8 +22,27 @@ var (
 
 	currentID = uint32(0)
 	quitOnce  sync.Once
+
+	// radioGroups maps a radio group, scoped to its parent menu item (0 for
+	// the top-level menu), to its members in creation order. Guarded by
+	// menuItemsLock.
+	radioGroups = make(map[radioGroupKey][]*menuItem)
+
+	trayIconsLock sync.RWMutex
+	trayIcons     = make(map[uint32]*TrayIcon)
+
+	defaultTrayOnce sync.Once
+	defaultTray     *TrayIcon
 )
 
+// radioGroupKey scopes a radio group's int identifier to the parent it lives
+// under, since the same group number may be reused independently in
+// different sub-menus.
+type radioGroupKey struct {
+	parentID uint32
+	group    int
+}
+
 func init() {
 	runtime.LockOSThread()
 }
@@ -33,12 +52,14 @@ type MenuItem interface {
 
 	AddSubMenuItem(title string, tooltip string) MenuItem
 	AddSubMenuItemCheckbox(title string, tooltip string, checked bool) MenuItem
+	AddSubMenuItemRadio(title string, tooltip string, group int, selected bool) MenuItem
 	Check()
 	Checked() bool
 	Disable()
 	Disabled() bool
 	Enable()
 	Hide()
+	Remove()
 	SetIcon(iconBytes []byte)
 	SetTemplateIcon(templateIconBytes []byte, regularIconBytes []byte)
 	SetTitle(title string)
@@ -70,8 +91,24 @@ type menuItem struct {
 	checked bool
 	// has the menu item a checkbox (Linux)
 	isCheckable bool
+	// isRadio marks the item as part of an exclusive, same-parent radio group
+	isRadio bool
+	// group identifies which radio group this item belongs to, meaningful
+	// only when isRadio is true; items share a group with their siblings
+	// under the same parent
+	group int
+	// icon is the regular icon bytes (PNG/ICO) most recently set via SetIcon or
+	// SetTemplateIcon; used as-is on Windows and Linux, and as the fallback
+	// image on macOS when no template icon is set
+	icon []byte
 	// parent item, for sub menus
 	parent MenuItem
+	// children holds the sub-menu items added under this one, in display
+	// order, so Remove() can tear them down recursively
+	children []MenuItem
+	// removed is set once Remove() has closed clickedCh, guarded by
+	// menuItemsLock so systrayMenuItemSelected never sends on a closed channel
+	removed bool
 }
 
 // id implements MenuItem.
@@ -100,6 +137,50 @@ func newMenuItem(title string, tooltip string, parent MenuItem) *menuItem {
 	}
 }
 
+// newRadioMenuItem returns a populated, checkable MenuItem object marked as
+// belonging to a radio group
+func newRadioMenuItem(title string, tooltip string, parent MenuItem, group int, selected bool) *menuItem {
+	item := newMenuItem(title, tooltip, parent)
+	item.isCheckable = true
+	item.isRadio = true
+	item.group = group
+	item.checked = selected
+	return item
+}
+
+// parentID returns the id used to scope a radio group to its parent, 0 for
+// the top-level menu.
+func parentID(parent MenuItem) uint32 {
+	if parent == nil {
+		return 0
+	}
+	return parent.id()
+}
+
+// registerRadioGroupMember records item under its (parent, group) key so
+// later selections can find and unselect its siblings.
+func registerRadioGroupMember(item *menuItem) {
+	key := radioGroupKey{parentID: parentID(item.parent), group: item.group}
+	menuItemsLock.Lock()
+	radioGroups[key] = append(radioGroups[key], item)
+	menuItemsLock.Unlock()
+}
+
+// applyRadioSelection checks item and unchecks every other member of its
+// radio group, then asks the native backend to render the swap.
+func applyRadioSelection(item *menuItem) {
+	key := radioGroupKey{parentID: parentID(item.parent), group: item.group}
+
+	menuItemsLock.Lock()
+	members := append([]*menuItem(nil), radioGroups[key]...)
+	for _, member := range members {
+		member.checked = member.id_ == item.id_
+	}
+	menuItemsLock.Unlock()
+
+	selectRadioGroupMember(item, members)
+}
+
 // Run initializes GUI and starts the event loop, then invokes the onReady callback. It blocks until
 // systray.Quit() is called. It must be run from the main thread on macOS.
 func Run(onReady func(), onExit func()) {
@@ -140,6 +221,164 @@ func Quit() {
 	quitOnce.Do(quit)
 }
 
+// TrayIcon represents a single icon, title, and tooltip shown in the
+// notification area. Most applications only need one, created implicitly the
+// first time one of the package-level SetIcon/SetTitle/SetTooltip/
+// SetTemplateIcon functions is called. Call NewTrayIcon directly when an
+// application wants several independent icons side by side, for example a
+// backup status icon next to an activity icon.
+type TrayIcon struct {
+	id uint32
+
+	lock         sync.Mutex
+	title        string
+	tooltip      string
+	onLeftClick  func()
+	onRightClick func()
+}
+
+// NewTrayIcon creates and shows a new, independent tray icon. It can be
+// safely invoked from different goroutines.
+func NewTrayIcon() *TrayIcon {
+	tray := &TrayIcon{id: atomic.AddUint32(&currentID, 1)}
+	trayIconsLock.Lock()
+	trayIcons[tray.id] = tray
+	trayIconsLock.Unlock()
+	registerTrayIcon(tray)
+	return tray
+}
+
+// getDefaultTray lazily creates the default TrayIcon backing the
+// package-level SetIcon/SetTitle/SetTooltip/SetTemplateIcon functions.
+func getDefaultTray() *TrayIcon {
+	defaultTrayOnce.Do(func() {
+		defaultTray = NewTrayIcon()
+	})
+	return defaultTray
+}
+
+// SetIcon sets this tray icon's image.
+func (t *TrayIcon) SetIcon(iconBytes []byte) {
+	setTrayIcon(t, nil, iconBytes)
+}
+
+// SetTemplateIcon sets this tray icon's image as a template icon (on macOS).
+// On Windows and Linux, regularIconBytes is rendered instead, since neither
+// platform has a template-image concept.
+func (t *TrayIcon) SetTemplateIcon(templateIconBytes []byte, regularIconBytes []byte) {
+	setTrayIcon(t, templateIconBytes, regularIconBytes)
+}
+
+// SetTitle sets this tray icon's title text, shown next to the icon on
+// platforms that render one (e.g. macOS's menu bar).
+func (t *TrayIcon) SetTitle(title string) {
+	t.lock.Lock()
+	t.title = title
+	t.lock.Unlock()
+	setTrayIconTitle(t, title)
+}
+
+// Title returns this tray icon's most recently set title.
+func (t *TrayIcon) Title() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.title
+}
+
+// SetTooltip sets the text shown when the mouse hovers over this tray icon.
+// On Linux the text is only stored and returned by Tooltip; nothing is shown.
+func (t *TrayIcon) SetTooltip(tooltip string) {
+	t.lock.Lock()
+	t.tooltip = tooltip
+	t.lock.Unlock()
+	setTrayIconTooltip(t, tooltip)
+}
+
+// Tooltip returns this tray icon's most recently set tooltip.
+func (t *TrayIcon) Tooltip() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.tooltip
+}
+
+// OnLeftClick registers fn to be called, on its own goroutine, whenever this
+// tray icon receives a left click. It replaces any previously registered
+// callback. Clicks are only reported on Windows; on macOS and Linux fn is
+// never called.
+func (t *TrayIcon) OnLeftClick(fn func()) {
+	t.lock.Lock()
+	t.onLeftClick = fn
+	t.lock.Unlock()
+}
+
+// OnRightClick registers fn to be called, on its own goroutine, whenever this
+// tray icon receives a right click. It replaces any previously registered
+// callback. Clicks are only reported on Windows; on macOS and Linux fn is
+// never called.
+func (t *TrayIcon) OnRightClick(fn func()) {
+	t.lock.Lock()
+	t.onRightClick = fn
+	t.lock.Unlock()
+}
+
+// Remove hides and destroys this tray icon natively. It can be safely invoked
+// from different goroutines.
+func (t *TrayIcon) Remove() {
+	trayIconsLock.Lock()
+	delete(trayIcons, t.id)
+	trayIconsLock.Unlock()
+	removeTrayIcon(t)
+}
+
+// trayIconClicked invokes the left- or right-click callback registered for
+// the tray icon identified by id. Called by the native backend when the user
+// clicks a tray icon.
+func trayIconClicked(id uint32, rightButton bool) {
+	trayIconsLock.RLock()
+	tray, ok := trayIcons[id]
+	trayIconsLock.RUnlock()
+	if !ok {
+		log.Errorf("No tray icon with ID %v", id)
+		return
+	}
+
+	tray.lock.Lock()
+	fn := tray.onLeftClick
+	if rightButton {
+		fn = tray.onRightClick
+	}
+	tray.lock.Unlock()
+
+	if fn != nil {
+		go fn()
+	}
+}
+
+// SetIcon sets the icon of the default tray icon. It can be safely invoked
+// from different goroutines.
+func SetIcon(iconBytes []byte) {
+	getDefaultTray().SetIcon(iconBytes)
+}
+
+// SetTemplateIcon sets the icon of the default tray icon as a template icon
+// (on macOS). On Windows and Linux, regularIconBytes is rendered instead. It
+// can be safely invoked from different goroutines.
+func SetTemplateIcon(templateIconBytes []byte, regularIconBytes []byte) {
+	getDefaultTray().SetTemplateIcon(templateIconBytes, regularIconBytes)
+}
+
+// SetTitle sets the title of the default tray icon. It can be safely invoked
+// from different goroutines.
+func SetTitle(title string) {
+	getDefaultTray().SetTitle(title)
+}
+
+// SetTooltip sets the tooltip of the default tray icon. It can be safely
+// invoked from different goroutines.
+func SetTooltip(tooltip string) {
+	getDefaultTray().SetTooltip(tooltip)
+}
+
 // AddMenuItem adds a menu item with the designated title and tooltip.
 // It can be safely invoked from different goroutines.
 // Created menu items are checkable on Windows and OSX by default. For Linux you have to use AddMenuItemCheckbox
@@ -160,11 +399,80 @@ func AddMenuItemCheckbox(title string, tooltip string, checked bool) *menuItem {
 	return item
 }
 
+// AddMenuItemRadio adds a menu item with the designated title and tooltip,
+// belonging to the given radio group. Selecting it automatically unchecks
+// every other top-level item in the same group and fires only its own
+// ClickedCh. It can be safely invoked from different goroutines.
+func AddMenuItemRadio(title string, tooltip string, group int, selected bool) *menuItem {
+	item := newRadioMenuItem(title, tooltip, nil, group, selected)
+	registerRadioGroupMember(item)
+	item.update()
+	registerNativeRadioGroup(item)
+	if selected {
+		applyRadioSelection(item)
+	}
+	return item
+}
+
 // AddSeparator adds a separator bar to the menu
 func AddSeparator() {
 	addSeparator(atomic.AddUint32(&currentID, 1))
 }
 
+// InsertMenuItemBefore creates a new menu item with the designated title and
+// tooltip and inserts it immediately before sibling, as a sibling of it (in
+// the top-level menu or under the same parent). It can be safely invoked from
+// different goroutines, which lets dynamic menus be rebuilt in place without
+// tearing down the whole tray.
+func InsertMenuItemBefore(sibling MenuItem, title string, tooltip string) MenuItem {
+	s := sibling.(*menuItem)
+	item := newMenuItem(title, tooltip, s.parent)
+	menuItemsLock.Lock()
+	menuItems[item.id_] = item
+	if s.parent != nil {
+		s.parent.(*menuItem).insertChildBefore(item, s)
+	}
+	menuItemsLock.Unlock()
+	insertMenuItemBefore(item, s)
+	return item
+}
+
+// InsertSeparatorBefore adds a separator bar immediately before sibling. It
+// can be safely invoked from different goroutines.
+func InsertSeparatorBefore(sibling MenuItem) {
+	id := atomic.AddUint32(&currentID, 1)
+	insertSeparatorBefore(id, sibling.(*menuItem).id_)
+}
+
+// RemoveAllMenuItems removes every top-level menu item, and recursively their
+// sub-menu items, tearing down each one natively. It can be safely invoked
+// from different goroutines.
+func RemoveAllMenuItems() {
+	menuItemsLock.RLock()
+	roots := make([]MenuItem, 0, len(menuItems))
+	for _, item := range menuItems {
+		if item.(*menuItem).parent == nil {
+			roots = append(roots, item)
+		}
+	}
+	menuItemsLock.RUnlock()
+	for _, item := range roots {
+		item.Remove()
+	}
+}
+
+// insertChildBefore splices child into item.children immediately before
+// sibling. Callers must hold menuItemsLock.
+func (item *menuItem) insertChildBefore(child *menuItem, sibling *menuItem) {
+	for i, existing := range item.children {
+		if existing.id() == sibling.id_ {
+			item.children = append(item.children[:i], append([]MenuItem{child}, item.children[i:]...)...)
+			return
+		}
+	}
+	item.children = append(item.children, child)
+}
+
 // Run initializes GUI and starts the event loop, then invokes the onReady callback. It blocks until
 // systray.Quit() is called. It must be run from the main thread on macOS.
 func (item *menuItem) ClickedCh() chan struct{} {
@@ -177,6 +485,9 @@ func (item *menuItem) ClickedCh() chan struct{} {
 func (item *menuItem) AddSubMenuItem(title string, tooltip string) MenuItem {
 	child := newMenuItem(title, tooltip, item)
 	child.update()
+	menuItemsLock.Lock()
+	item.children = append(item.children, child)
+	menuItemsLock.Unlock()
 	return child
 }
 
@@ -188,6 +499,27 @@ func (item *menuItem) AddSubMenuItemCheckbox(title string, tooltip string, check
 	child.isCheckable = true
 	child.checked = checked
 	child.update()
+	menuItemsLock.Lock()
+	item.children = append(item.children, child)
+	menuItemsLock.Unlock()
+	return child
+}
+
+// AddSubMenuItemRadio adds a nested sub-menu item belonging to the given
+// radio group, scoped to this parent. Selecting it automatically unchecks
+// every other sub-menu item of item in the same group and fires only its own
+// ClickedCh. It can be safely invoked from different goroutines.
+func (item *menuItem) AddSubMenuItemRadio(title string, tooltip string, group int, selected bool) MenuItem {
+	child := newRadioMenuItem(title, tooltip, item, group, selected)
+	registerRadioGroupMember(child)
+	child.update()
+	registerNativeRadioGroup(child)
+	menuItemsLock.Lock()
+	item.children = append(item.children, child)
+	menuItemsLock.Unlock()
+	if selected {
+		applyRadioSelection(child)
+	}
 	return child
 }
 
@@ -203,6 +535,22 @@ func (item *menuItem) SetTooltip(tooltip string) {
 	item.update()
 }
 
+// SetIcon sets the icon of a menu item, rendered natively next to the title on
+// all three platforms. On Linux, checkbox and radio items show no icon.
+func (item *menuItem) SetIcon(iconBytes []byte) {
+	item.icon = iconBytes
+	setMenuItemIcon(item, nil, iconBytes)
+}
+
+// SetTemplateIcon sets the icon of a menu item as a template icon (on macOS).
+// templateIconBytes should be a monochrome image with alpha channel.
+// On Windows and Linux, templateIconBytes is ignored and regularIconBytes is
+// rendered instead, since neither platform has a template-image concept.
+func (item *menuItem) SetTemplateIcon(templateIconBytes []byte, regularIconBytes []byte) {
+	item.icon = regularIconBytes
+	setMenuItemIcon(item, templateIconBytes, regularIconBytes)
+}
+
 // Disabled checks if the menu item is disabled
 func (item *menuItem) Disabled() bool {
 	return item.disabled
@@ -230,6 +578,45 @@ func (item *menuItem) Show() {
 	showMenuItem(item)
 }
 
+// Remove deletes the menu item natively, recursively removing any sub-menu
+// items first. After Remove returns, the item's ClickedCh is closed and it is
+// no longer tracked by the package; it must not be used again.
+func (item *menuItem) Remove() {
+	menuItemsLock.RLock()
+	children := append([]MenuItem(nil), item.children...)
+	menuItemsLock.RUnlock()
+	for _, child := range children {
+		child.Remove()
+	}
+
+	removeMenuItem(item)
+	destroyMenuItemIcon(item.id_)
+
+	menuItemsLock.Lock()
+	delete(menuItems, item.id_)
+	if parent, ok := item.parent.(*menuItem); ok {
+		for i, sibling := range parent.children {
+			if sibling.id() == item.id_ {
+				parent.children = append(parent.children[:i], parent.children[i+1:]...)
+				break
+			}
+		}
+	}
+	if item.isRadio {
+		key := radioGroupKey{parentID: parentID(item.parent), group: item.group}
+		members := radioGroups[key]
+		for i, member := range members {
+			if member.id_ == item.id_ {
+				radioGroups[key] = append(members[:i], members[i+1:]...)
+				break
+			}
+		}
+	}
+	item.removed = true
+	close(item.clickedCh)
+	menuItemsLock.Unlock()
+}
+
 // Checked returns if the menu item has a check mark
 func (item *menuItem) Checked() bool {
 	return item.checked
@@ -263,6 +650,18 @@ func systrayMenuItemSelected(id uint32) {
 		log.Errorf("No menu item with ID %v", id)
 		return
 	}
+	if radioItem, ok := item.(*menuItem); ok && radioItem.isRadio {
+		applyRadioSelection(radioItem)
+	}
+
+	// Re-check under the lock immediately before sending: Remove() closes
+	// clickedCh and sets removed under the same lock, so holding it across
+	// the send guarantees we never write to an already-closed channel.
+	menuItemsLock.RLock()
+	defer menuItemsLock.RUnlock()
+	if menuItem, ok := item.(*menuItem); ok && menuItem.removed {
+		return
+	}
 	select {
 	case item.ClickedCh() <- struct{}{}:
 	// in case no one waiting for the channel
@@ -0,0 +1,121 @@
+//go:build darwin
+
+package systray
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework Cocoa
+// #import <Cocoa/Cocoa.h>
+// #include <stdlib.h>
+//
+// static void *newStatusItem(void) {
+//     NSStatusItem *statusItem = [[NSStatusBar systemStatusBar] statusItemWithLength:NSVariableStatusItemLength];
+//     return (void *)[statusItem retain];
+// }
+//
+// static void setStatusItemIcon(void *statusItem, const void *bytes, int length, bool isTemplate) {
+//     NSStatusItem *item = (NSStatusItem *)statusItem;
+//     NSData *data = [NSData dataWithBytes:bytes length:length];
+//     NSImage *image = [[NSImage alloc] initWithData:data];
+//     [image setTemplate:isTemplate];
+//     [item.button setImage:image];
+//     [image release];
+// }
+//
+// static void setStatusItemTitle(void *statusItem, const char *title) {
+//     NSStatusItem *item = (NSStatusItem *)statusItem;
+//     [item.button setTitle:[NSString stringWithUTF8String:title]];
+// }
+//
+// static void setStatusItemTooltip(void *statusItem, const char *tooltip) {
+//     NSStatusItem *item = (NSStatusItem *)statusItem;
+//     [item.button setToolTip:[NSString stringWithUTF8String:tooltip]];
+// }
+//
+// static void removeStatusItem(void *statusItem) {
+//     NSStatusItem *item = (NSStatusItem *)statusItem;
+//     [[NSStatusBar systemStatusBar] removeStatusItem:item];
+//     [item release];
+// }
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+var (
+	statusItemsLock sync.Mutex
+	// statusItems tracks the NSStatusItem backing each TrayIcon.
+	statusItems = make(map[uint32]unsafe.Pointer)
+)
+
+// registerTrayIcon creates tray's own NSStatusItem via
+// -[NSStatusBar statusItemWithLength:NSVariableStatusItemLength].
+func registerTrayIcon(tray *TrayIcon) {
+	statusItem := C.newStatusItem()
+	statusItemsLock.Lock()
+	statusItems[tray.id] = statusItem
+	statusItemsLock.Unlock()
+}
+
+// statusItemFor returns the NSStatusItem backing a tray icon, or nil if it
+// hasn't been realized natively yet.
+func statusItemFor(id uint32) unsafe.Pointer {
+	statusItemsLock.Lock()
+	defer statusItemsLock.Unlock()
+	return statusItems[id]
+}
+
+// setTrayIcon applies iconBytes as tray's NSStatusItem image, marking it as a
+// template image when set via SetTemplateIcon.
+func setTrayIcon(tray *TrayIcon, templateIconBytes []byte, regularIconBytes []byte) {
+	statusItem := statusItemFor(tray.id)
+	if statusItem == nil {
+		return
+	}
+	iconBytes := templateIconBytes
+	isTemplate := true
+	if iconBytes == nil {
+		iconBytes = regularIconBytes
+		isTemplate = false
+	}
+	if len(iconBytes) == 0 {
+		return
+	}
+	C.setStatusItemIcon(statusItem, unsafe.Pointer(&iconBytes[0]), C.int(len(iconBytes)), C.bool(isTemplate))
+}
+
+// setTrayIconTitle sets tray's NSStatusItem title text.
+func setTrayIconTitle(tray *TrayIcon, title string) {
+	statusItem := statusItemFor(tray.id)
+	if statusItem == nil {
+		return
+	}
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+	C.setStatusItemTitle(statusItem, cTitle)
+}
+
+// setTrayIconTooltip sets tray's NSStatusItem tooltip text.
+func setTrayIconTooltip(tray *TrayIcon, tooltip string) {
+	statusItem := statusItemFor(tray.id)
+	if statusItem == nil {
+		return
+	}
+	cTooltip := C.CString(tooltip)
+	defer C.free(unsafe.Pointer(cTooltip))
+	C.setStatusItemTooltip(statusItem, cTooltip)
+}
+
+// removeTrayIcon removes tray's NSStatusItem from the status bar via
+// -[NSStatusBar removeStatusItem:] and forgets it.
+func removeTrayIcon(tray *TrayIcon) {
+	statusItemsLock.Lock()
+	statusItem, ok := statusItems[tray.id]
+	delete(statusItems, tray.id)
+	statusItemsLock.Unlock()
+	if !ok {
+		return
+	}
+	C.removeStatusItem(statusItem)
+}
@@ -0,0 +1,131 @@
+//go:build linux
+
+package systray
+
+// #cgo pkg-config: appindicator3-0.1 gtk+-3.0
+// #include <libappindicator/app-indicator.h>
+// #include <stdlib.h>
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+)
+
+var (
+	indicatorsLock sync.Mutex
+	// indicators tracks the AppIndicator backing each TrayIcon.
+	indicators = make(map[uint32]*C.AppIndicator)
+	// iconTempDirs tracks the temp directory backing each indicator's on-disk
+	// icon file, since AppIndicator only accepts icon names/paths, not bytes.
+	iconTempDirs = make(map[uint32]string)
+)
+
+// registerTrayIcon creates tray's own AppIndicator, identified by a unique
+// app id so it shows as an independent icon in the status area.
+func registerTrayIcon(tray *TrayIcon) {
+	id := C.CString(fmt.Sprintf("systray-%d", tray.id))
+	defer C.free(unsafe.Pointer(id))
+	icon := C.CString("")
+	defer C.free(unsafe.Pointer(icon))
+
+	indicator := C.app_indicator_new(id, icon, C.APP_INDICATOR_CATEGORY_APPLICATION_STATUS)
+	C.app_indicator_set_status(indicator, C.APP_INDICATOR_STATUS_ACTIVE)
+
+	indicatorsLock.Lock()
+	indicators[tray.id] = indicator
+	indicatorsLock.Unlock()
+}
+
+// setTrayIcon writes iconBytes to a temp file and points tray's AppIndicator
+// at it, since libappindicator loads icons by name/path rather than bytes.
+// Linux has no template-icon concept, so templateIconBytes is ignored and
+// regularIconBytes is always rendered.
+func setTrayIcon(tray *TrayIcon, templateIconBytes []byte, regularIconBytes []byte) {
+	iconBytes := regularIconBytes
+	if len(iconBytes) == 0 {
+		return
+	}
+
+	indicatorsLock.Lock()
+	indicator, ok := indicators[tray.id]
+	indicatorsLock.Unlock()
+	if !ok {
+		return
+	}
+
+	removeIconTempFile(tray.id)
+	path, err := writeIconTempFile(tray.id, iconBytes)
+	if err != nil {
+		log.Errorf("Unable to write icon for tray icon %d: %v", tray.id, err)
+		return
+	}
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	C.app_indicator_set_icon_full(indicator, cPath, cPath)
+}
+
+// setTrayIconTitle sets tray's AppIndicator label, shown next to the icon.
+func setTrayIconTitle(tray *TrayIcon, title string) {
+	indicatorsLock.Lock()
+	indicator, ok := indicators[tray.id]
+	indicatorsLock.Unlock()
+	if !ok {
+		return
+	}
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+	C.app_indicator_set_label(indicator, cTitle, cTitle)
+}
+
+// setTrayIconTooltip is a no-op on Linux: AppIndicator/StatusNotifierItem has
+// no direct hover-tooltip setter.
+func setTrayIconTooltip(tray *TrayIcon, tooltip string) {}
+
+// removeTrayIcon marks tray's AppIndicator passive, releases it, and cleans up
+// its temporary icon file.
+func removeTrayIcon(tray *TrayIcon) {
+	indicatorsLock.Lock()
+	indicator, ok := indicators[tray.id]
+	delete(indicators, tray.id)
+	indicatorsLock.Unlock()
+	if ok {
+		C.app_indicator_set_status(indicator, C.APP_INDICATOR_STATUS_PASSIVE)
+		C.g_object_unref(C.gpointer(unsafe.Pointer(indicator)))
+	}
+	removeIconTempFile(tray.id)
+}
+
+// writeIconTempFile saves iconBytes to a fresh temp directory and returns the
+// resulting file's path.
+func writeIconTempFile(id uint32, iconBytes []byte) (string, error) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("systray-tray-%d-", id))
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "icon.png")
+	if err := os.WriteFile(path, iconBytes, 0o600); err != nil {
+		return "", err
+	}
+
+	indicatorsLock.Lock()
+	iconTempDirs[id] = dir
+	indicatorsLock.Unlock()
+	return path, nil
+}
+
+// removeIconTempFile removes the temp directory backing tray's on-disk icon,
+// if any, so long-running trays don't leak files.
+func removeIconTempFile(id uint32) {
+	indicatorsLock.Lock()
+	dir, ok := iconTempDirs[id]
+	delete(iconTempDirs, id)
+	indicatorsLock.Unlock()
+	if ok {
+		os.RemoveAll(dir)
+	}
+}
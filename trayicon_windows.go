@@ -0,0 +1,293 @@
+//go:build windows
+
+package systray
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	shell32           = windows.NewLazySystemDLL("shell32.dll")
+	pShellNotifyIconW = shell32.NewProc("Shell_NotifyIconW")
+
+	pRegisterClassExW = user32.NewProc("RegisterClassExW")
+	pCreateWindowExW  = user32.NewProc("CreateWindowExW")
+	pDefWindowProcW   = user32.NewProc("DefWindowProcW")
+
+	pCreateIconIndirect = user32.NewProc("CreateIconIndirect")
+	pDestroyIcon        = user32.NewProc("DestroyIcon")
+	pGetObjectW         = gdi32.NewProc("GetObjectW")
+	pCreateBitmap       = gdi32.NewProc("CreateBitmap")
+
+	notifyIconsLock sync.Mutex
+	// notifyIcons tracks each TrayIcon's uID so its NOTIFYICONDATA entry can
+	// be looked up for later NIM_MODIFY/NIM_DELETE calls.
+	notifyIcons = make(map[uint32]*TrayIcon)
+
+	// trayIconHandles tracks the HICON created for each tray icon's image so
+	// it can be destroyed when the image is replaced or the icon is removed.
+	trayIconHandlesLock sync.Mutex
+	trayIconHandles     = make(map[uint32]windows.Handle)
+
+	trayWndOnce sync.Once
+	trayHWND    uintptr
+)
+
+const (
+	nimAdd    = 0x00000000
+	nimModify = 0x00000001
+	nimDelete = 0x00000002
+
+	nifMessage = 0x00000001
+	nifIcon    = 0x00000002
+	nifTip     = 0x00000004
+
+	// wmTrayIconCallback is the uCallbackMessage Shell_NotifyIconW posts back
+	// to trayWndProc on mouse activity over a tray icon.
+	wmTrayIconCallback = 0x8000 + 1 // WM_APP+1
+	wmLButtonUp        = 0x0202
+	wmRButtonUp        = 0x0205
+
+	hwndMessageOnly = ^uintptr(2) // HWND_MESSAGE, i.e. (HWND)-3
+)
+
+// notifyIconDataW mirrors the fields of Win32's NOTIFYICONDATAW needed to
+// add, modify, and remove a tray icon keyed by uID.
+type notifyIconDataW struct {
+	cbSize           uint32
+	hWnd             uintptr
+	uID              uint32
+	uFlags           uint32
+	uCallbackMessage uint32
+	hIcon            uintptr
+	szTip            [128]uint16
+	dwState          uint32
+	dwStateMask      uint32
+	szInfo           [256]uint16
+	uVersion         uint32
+	szInfoTitle      [64]uint16
+	dwInfoFlags      uint32
+	guidItem         [16]byte
+	hBalloonIcon     uintptr
+}
+
+// wndClassExW mirrors the fields of Win32's WNDCLASSEXW needed to register
+// trayWndProc.
+type wndClassExW struct {
+	cbSize        uint32
+	style         uint32
+	lpfnWndProc   uintptr
+	cbClsExtra    int32
+	cbWndExtra    int32
+	hInstance     windows.Handle
+	hIcon         windows.Handle
+	hCursor       windows.Handle
+	hbrBackground windows.Handle
+	lpszMenuName  *uint16
+	lpszClassName *uint16
+	hIconSm       windows.Handle
+}
+
+// bitmapStruct mirrors the fields of Win32's BITMAP needed to read back a
+// DIB section's dimensions via GetObjectW.
+type bitmapStruct struct {
+	bmType       int32
+	bmWidth      int32
+	bmHeight     int32
+	bmWidthBytes int32
+	bmPlanes     uint16
+	bmBitsPixel  uint16
+	bmBits       uintptr
+}
+
+// iconInfo mirrors the fields of Win32's ICONINFO needed by
+// CreateIconIndirect.
+type iconInfo struct {
+	fIcon    int32
+	xHotspot uint32
+	yHotspot uint32
+	hbmMask  windows.Handle
+	hbmColor windows.Handle
+}
+
+// hbitmapToHICON wraps a 32bpp color HBITMAP (as produced by
+// iconBytesToHBITMAP) in an HICON via CreateIconIndirect, which is what
+// NOTIFYICONDATA.hIcon actually requires. The mask bitmap is an all-zero
+// monochrome bitmap, since the color bitmap already carries its own alpha
+// channel.
+func hbitmapToHICON(hbitmap windows.Handle) (windows.Handle, error) {
+	var bm bitmapStruct
+	if ret, _, _ := pGetObjectW.Call(uintptr(hbitmap), unsafe.Sizeof(bm), uintptr(unsafe.Pointer(&bm))); ret == 0 {
+		return 0, fmt.Errorf("GetObjectW failed for tray icon bitmap")
+	}
+
+	hmask, _, _ := pCreateBitmap.Call(uintptr(bm.bmWidth), uintptr(bm.bmHeight), 1, 1, 0)
+	if hmask == 0 {
+		return 0, fmt.Errorf("CreateBitmap failed for tray icon mask")
+	}
+	defer pDeleteObject.Call(hmask)
+
+	info := iconInfo{fIcon: 1, hbmMask: windows.Handle(hmask), hbmColor: hbitmap}
+	hicon, _, callErr := pCreateIconIndirect.Call(uintptr(unsafe.Pointer(&info)))
+	if hicon == 0 {
+		return 0, callErr
+	}
+	return windows.Handle(hicon), nil
+}
+
+// trayWindow lazily registers a hidden, message-only window whose sole job is
+// to receive Shell_NotifyIconW's mouse-activity callbacks and route them to
+// trayIconClicked.
+func trayWindow() uintptr {
+	trayWndOnce.Do(func() {
+		className, _ := syscall.UTF16PtrFromString("SystrayTrayIconWnd")
+		wc := wndClassExW{
+			lpfnWndProc:   syscall.NewCallback(trayWndProc),
+			lpszClassName: className,
+		}
+		wc.cbSize = uint32(unsafe.Sizeof(wc))
+		pRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc)))
+
+		hwnd, _, _ := pCreateWindowExW.Call(
+			0,
+			uintptr(unsafe.Pointer(className)),
+			0,
+			0, 0, 0, 0, 0,
+			hwndMessageOnly,
+			0, 0, 0,
+		)
+		trayHWND = hwnd
+	})
+	return trayHWND
+}
+
+// trayWndProc dispatches wmTrayIconCallback messages to trayIconClicked,
+// identifying the icon by its uID (wParam) and the originating mouse message
+// (the low word of lParam).
+func trayWndProc(hwnd uintptr, msg uint32, wParam, lParam uintptr) uintptr {
+	if msg == wmTrayIconCallback {
+		switch uint32(lParam) {
+		case wmLButtonUp:
+			trayIconClicked(uint32(wParam), false)
+		case wmRButtonUp:
+			trayIconClicked(uint32(wParam), true)
+		}
+		return 0
+	}
+	ret, _, _ := pDefWindowProcW.Call(hwnd, uintptr(msg), wParam, lParam)
+	return ret
+}
+
+// registerTrayIcon adds tray's NOTIFYICONDATA entry to the notification area
+// via Shell_NotifyIconW(NIM_ADD, ...), owned by the shared hidden tray window
+// so mouse clicks are routed back to it.
+func registerTrayIcon(tray *TrayIcon) {
+	notifyIconsLock.Lock()
+	notifyIcons[tray.id] = tray
+	notifyIconsLock.Unlock()
+
+	nid := notifyIconDataW{
+		hWnd:             trayWindow(),
+		uID:              tray.id,
+		uFlags:           nifMessage,
+		uCallbackMessage: wmTrayIconCallback,
+	}
+	nid.cbSize = uint32(unsafe.Sizeof(nid))
+	pShellNotifyIconW.Call(nimAdd, uintptr(unsafe.Pointer(&nid)))
+}
+
+// setTrayIcon renders iconBytes as tray's notification-area icon via
+// Shell_NotifyIconW(NIM_MODIFY, ...), converting the decoded bitmap to an
+// HICON since NOTIFYICONDATA.hIcon requires one. Windows has no
+// template-icon concept, so templateIconBytes is ignored and
+// regularIconBytes is always rendered.
+func setTrayIcon(tray *TrayIcon, templateIconBytes []byte, regularIconBytes []byte) {
+	iconBytes := regularIconBytes
+	if len(iconBytes) == 0 {
+		destroyTrayIconHandle(tray.id)
+		return
+	}
+
+	hbitmap, err := iconBytesToHBITMAP(iconBytes)
+	if err != nil {
+		log.Errorf("Unable to convert icon for tray icon %d: %v", tray.id, err)
+		return
+	}
+	hicon, err := hbitmapToHICON(hbitmap)
+	pDeleteObject.Call(uintptr(hbitmap))
+	if err != nil {
+		log.Errorf("Unable to create icon for tray icon %d: %v", tray.id, err)
+		return
+	}
+
+	destroyTrayIconHandle(tray.id)
+	trayIconHandlesLock.Lock()
+	trayIconHandles[tray.id] = hicon
+	trayIconHandlesLock.Unlock()
+
+	nid := notifyIconDataW{hWnd: trayWindow(), uID: tray.id, uFlags: nifIcon, hIcon: uintptr(hicon)}
+	nid.cbSize = uint32(unsafe.Sizeof(nid))
+	pShellNotifyIconW.Call(nimModify, uintptr(unsafe.Pointer(&nid)))
+}
+
+// destroyTrayIconHandle releases the HICON previously associated with a tray
+// icon, if any. Called whenever the icon is replaced and when the tray icon
+// itself is removed, so long-running trays don't leak GDI handles.
+func destroyTrayIconHandle(id uint32) {
+	trayIconHandlesLock.Lock()
+	hicon, ok := trayIconHandles[id]
+	if ok {
+		delete(trayIconHandles, id)
+	}
+	trayIconHandlesLock.Unlock()
+	if ok {
+		pDestroyIcon.Call(uintptr(hicon))
+	}
+}
+
+// setTrayIconTooltip sets tray's hover tooltip via
+// Shell_NotifyIconW(NIM_MODIFY, ...).
+func setTrayIconTooltip(tray *TrayIcon, tooltip string) {
+	nid := notifyIconDataW{hWnd: trayWindow(), uID: tray.id, uFlags: nifTip}
+	nid.cbSize = uint32(unsafe.Sizeof(nid))
+	copyStringToUTF16(nid.szTip[:], tooltip)
+	pShellNotifyIconW.Call(nimModify, uintptr(unsafe.Pointer(&nid)))
+}
+
+// setTrayIconTitle is a no-op on Windows: the notification area has no title
+// text distinct from the tooltip.
+func setTrayIconTitle(tray *TrayIcon, title string) {}
+
+// removeTrayIcon removes tray's NOTIFYICONDATA entry via
+// Shell_NotifyIconW(NIM_DELETE, ...) and releases its icon handle.
+func removeTrayIcon(tray *TrayIcon) {
+	notifyIconsLock.Lock()
+	delete(notifyIcons, tray.id)
+	notifyIconsLock.Unlock()
+
+	nid := notifyIconDataW{hWnd: trayWindow(), uID: tray.id}
+	nid.cbSize = uint32(unsafe.Sizeof(nid))
+	pShellNotifyIconW.Call(nimDelete, uintptr(unsafe.Pointer(&nid)))
+
+	destroyTrayIconHandle(tray.id)
+}
+
+// copyStringToUTF16 encodes s into dst as a NUL-terminated UTF-16 string,
+// truncating if necessary to fit.
+func copyStringToUTF16(dst []uint16, s string) {
+	encoded, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return
+	}
+	n := copy(dst, encoded)
+	if n < len(dst) {
+		dst[n] = 0
+	} else if n > 0 {
+		dst[n-1] = 0
+	}
+}